@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// requestIDHeader carries the request ID end to end: the Lambda adapter
+// sets it from events.APIGatewayProxyRequest.RequestContext.RequestID,
+// withRequestID mints one locally when it's missing, and the error
+// envelope and logger both read it back off the request.
+const requestIDHeader = "X-Request-Id"
+
+// withRequestID ensures every request carries a request ID, generating
+// one when running outside API Gateway (e.g. under LOCAL_HTTP=1), and
+// echoes it back on the response so a client can hand it to support.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+			r.Header.Set(requestIDHeader, id)
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// lambdaAdapter wraps an http.Handler so the exact same handler that
+// runs under `http.ListenAndServe` locally also runs behind Lambda and
+// API Gateway, by translating events.APIGatewayProxyRequest into an
+// *http.Request and capturing the ResponseWriter back into an
+// events.APIGatewayProxyResponse.
+func lambdaAdapter(h http.Handler) func(events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		httpReq, err := toHTTPRequest(req)
+		if err != nil {
+			return events.APIGatewayProxyResponse{}, err
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httpReq)
+
+		return toProxyResponse(rec)
+	}
+}
+
+func toHTTPRequest(req events.APIGatewayProxyRequest) (*http.Request, error) {
+	target := req.Path
+	if len(req.QueryStringParameters) > 0 {
+		values := url.Values{}
+		for k, v := range req.QueryStringParameters {
+			values.Set(k, v)
+		}
+		target += "?" + values.Encode()
+	}
+
+	httpReq, err := http.NewRequest(req.HTTPMethod, target, strings.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set(requestIDHeader, req.RequestContext.RequestID)
+
+	return httpReq, nil
+}
+
+func toProxyResponse(rec *httptest.ResponseRecorder) (events.APIGatewayProxyResponse, error) {
+	result := rec.Result()
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	headers := make(map[string]string, len(result.Header))
+	for k := range result.Header {
+		headers[k] = result.Header.Get(k)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: result.StatusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}, nil
+}
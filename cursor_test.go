@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{
+		"isbn": {S: aws.String("9780306406157")},
+	}
+
+	cursor, err := encodeCursor(key)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+
+	got, err := decodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, key) {
+		t.Errorf("decodeCursor(encodeCursor(key)) = %+v, want %+v", got, key)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	if _, err := decodeCursor("not-valid-base64!!"); err == nil {
+		t.Fatal("decodeCursor: expected error for malformed cursor, got nil")
+	} else if _, ok := err.(*cursorError); !ok {
+		t.Errorf("decodeCursor: error type = %T, want *cursorError", err)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// defaultListLimit is used for GET /books when the caller does not supply
+// a ?limit= query string parameter.
+const defaultListLimit = 20
+
+var db = dynamodb.New(session.Must(session.NewSession()))
+
+var tableName = os.Getenv("BOOKS_TABLE")
+
+// bookPage is a single page of a paginated book listing. NextCursor is
+// omitted once the scan has reached the end of the table.
+type bookPage struct {
+	Books      []*book `json:"books"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+func getItem(isbn string) (*book, error) {
+	result, err := db.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"isbn": {S: aws.String(isbn)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	bk := new(book)
+	if err := dynamodbattribute.UnmarshalMap(result.Item, bk); err != nil {
+		return nil, err
+	}
+	return bk, nil
+}
+
+// putItem writes bk, assigning it a fresh Version. If ifMatch is
+// non-empty, the write is conditioned on the stored item's version
+// still matching it, so two concurrent writers can't silently clobber
+// each other; a mismatch surfaces as a ConditionalCheckFailedException
+// (see isConditionalCheckFailed).
+func putItem(bk *book, ifMatch string) error {
+	bk.Version = newVersion()
+
+	item, err := dynamodbattribute.MarshalMap(bk)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      item,
+	}
+	if ifMatch != "" {
+		// "version" is a DynamoDB reserved word, so it must be aliased
+		// via ExpressionAttributeNames rather than used bare.
+		input.ConditionExpression = aws.String("#v = :ifMatch")
+		input.ExpressionAttributeNames = map[string]*string{
+			"#v": aws.String("version"),
+		}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":ifMatch": {S: aws.String(ifMatch)},
+		}
+	}
+
+	_, err = db.PutItem(input)
+	return err
+}
+
+// newVersion mints a new opaque version token, stored as the DynamoDB
+// "version" attribute and, quoted, as the book's ETag (see etagFor).
+func newVersion() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "0"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// listItems scans the table for up to limit books, optionally starting
+// from cursor (an opaque, base64-encoded LastEvaluatedKey) and filtering
+// on author. DynamoDB applies Limit to items scanned before author is
+// filtered, so a single Scan can come back with fewer than limit matches
+// (or none) while rows further in the table still qualify; listItems
+// keeps scanning subsequent segments until it has limit matches or the
+// table is exhausted, so NextCursor is only ever non-empty when more
+// matches may exist.
+func listItems(limit int, cursor, author string) (*bookPage, error) {
+	var startKey map[string]*dynamodb.AttributeValue
+	if cursor != "" {
+		key, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		startKey = key
+	}
+
+	books := make([]*book, 0, limit)
+	for len(books) < limit {
+		input := &dynamodb.ScanInput{
+			TableName:         aws.String(tableName),
+			Limit:             aws.Int64(int64(limit - len(books))),
+			ExclusiveStartKey: startKey,
+		}
+
+		if author != "" {
+			input.FilterExpression = aws.String("author = :author")
+			input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+				":author": {S: aws.String(author)},
+			}
+		}
+
+		result, err := db.Scan(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var segment []*book
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &segment); err != nil {
+			return nil, err
+		}
+		books = append(books, segment...)
+
+		startKey = result.LastEvaluatedKey
+		if len(startKey) == 0 {
+			break
+		}
+	}
+
+	page := &bookPage{Books: books}
+	if len(startKey) > 0 {
+		next, err := encodeCursor(startKey)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+	return page, nil
+}
+
+// errItemNotFound indicates updateItem's target isbn does not exist, as
+// distinct from errPreconditionFailed (the isbn exists but its version
+// no longer matches the caller's If-Match).
+var errItemNotFound = errors.New("item not found")
+
+// errPreconditionFailed indicates updateItem's conditional check failed
+// because the item's version had moved on from the caller's If-Match.
+var errPreconditionFailed = errors.New("precondition failed")
+
+// updateItem applies a partial update to the book identified by isbn.
+// fields maps attribute names (e.g. "title", "author") to their new
+// values; it must be non-empty. A fresh version is always assigned. If
+// ifMatch is non-empty, the update is additionally conditioned on the
+// stored item's version still matching it (see putItem); on failure,
+// updateItem re-checks existence so it can report errItemNotFound
+// separately from errPreconditionFailed rather than conflating the two.
+func updateItem(isbn string, fields map[string]string, ifMatch string) (*book, error) {
+	fields["version"] = newVersion()
+
+	names := make(map[string]*string, len(fields))
+	values := make(map[string]*dynamodb.AttributeValue, len(fields))
+	expr := "SET"
+	var versionPlaceholder string
+
+	i := 0
+	for field, value := range fields {
+		namePlaceholder := fmt.Sprintf("#f%d", i)
+		valuePlaceholder := fmt.Sprintf(":v%d", i)
+		if i > 0 {
+			expr += ","
+		}
+		expr += fmt.Sprintf(" %s = %s", namePlaceholder, valuePlaceholder)
+		names[namePlaceholder] = aws.String(field)
+		values[valuePlaceholder] = &dynamodb.AttributeValue{S: aws.String(value)}
+		if field == "version" {
+			versionPlaceholder = namePlaceholder
+		}
+		i++
+	}
+
+	// "version" is a DynamoDB reserved word; reuse the alias the SET
+	// clause already assigned it above rather than using it bare.
+	cond := "attribute_exists(isbn)"
+	if ifMatch != "" {
+		cond += fmt.Sprintf(" AND %s = :ifMatch", versionPlaceholder)
+		values[":ifMatch"] = &dynamodb.AttributeValue{S: aws.String(ifMatch)}
+	}
+
+	result, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"isbn": {S: aws.String(isbn)},
+		},
+		UpdateExpression:          aws.String(expr),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ConditionExpression:       aws.String(cond),
+		ReturnValues:              aws.String("ALL_NEW"),
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			existing, getErr := getItem(isbn)
+			if getErr != nil {
+				return nil, getErr
+			}
+			if existing == nil {
+				return nil, errItemNotFound
+			}
+			return nil, errPreconditionFailed
+		}
+		return nil, err
+	}
+
+	bk := new(book)
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, bk); err != nil {
+		return nil, err
+	}
+	return bk, nil
+}
+
+func deleteItem(isbn string) error {
+	_, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"isbn": {S: aws.String(isbn)},
+		},
+		ConditionExpression: aws.String("attribute_exists(isbn)"),
+	})
+	return err
+}
+
+// isConditionalCheckFailed reports whether err is a DynamoDB
+// ConditionalCheckFailedException: the item a PATCH/DELETE targeted did
+// not exist, or the stored version no longer matched a PUT/PATCH's
+// If-Match.
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+func encodeCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	js, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(js), nil
+}
+
+// cursorError marks a cursor as malformed input rather than a backend
+// failure, so handlers can return a 400 instead of a 500 for it.
+type cursorError struct {
+	reason error
+}
+
+func (e *cursorError) Error() string { return fmt.Sprintf("invalid cursor: %s", e.reason) }
+func (e *cursorError) Unwrap() error { return e.reason }
+
+func decodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	js, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, &cursorError{reason: err}
+	}
+
+	key := make(map[string]*dynamodb.AttributeValue)
+	if err := json.Unmarshal(js, &key); err != nil {
+		return nil, &cursorError{reason: err}
+	}
+	return key, nil
+}
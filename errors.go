@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Stable error codes returned in the "code" field of an error envelope.
+// Clients should match on these rather than on the message text or the
+// HTTP status, which may gain more granularity over time.
+const (
+	errCodeBadISBN            = "BAD_ISBN"
+	errCodeMissingFields      = "MISSING_FIELDS"
+	errCodeInvalidBody        = "INVALID_BODY"
+	errCodeInvalidLimit       = "INVALID_LIMIT"
+	errCodeInvalidCursor      = "INVALID_CURSOR"
+	errCodeUnsupportedMedia   = "UNSUPPORTED_MEDIA_TYPE"
+	errCodeNotFound           = "NOT_FOUND"
+	errCodeUnsupportedMethod  = "UNSUPPORTED_METHOD"
+	errCodePreconditionReq    = "PRECONDITION_REQUIRED"
+	errCodePreconditionFailed = "PRECONDITION_FAILED"
+	errCodeInternal           = "INTERNAL"
+)
+
+// apiError is a single entry in an error envelope's "errors" array.
+type apiError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// errorEnvelope is the JSON body written for every non-2xx response.
+type errorEnvelope struct {
+	Errors []apiError `json:"errors"`
+}
+
+// clientError writes a structured error envelope for a 4xx response,
+// tagged with the request's ID so a client can hand that ID to support.
+// Unlike serverError, it does not log anything.
+func clientError(w http.ResponseWriter, r *http.Request, code string, status int, message string) {
+	writeError(w, r, code, status, message)
+}
+
+// serverError logs err tagged with the request ID to os.Stderr and
+// writes a 500 Internal Server Error envelope.
+func serverError(w http.ResponseWriter, r *http.Request, err error) {
+	errorLogger.Printf("request_id=%s error=%s", r.Header.Get(requestIDHeader), err.Error())
+	writeError(w, r, errCodeInternal, http.StatusInternalServerError, "internal server error")
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, code string, status int, message string) {
+	requestID := r.Header.Get(requestIDHeader)
+	body := errorEnvelope{Errors: []apiError{{Code: code, Message: message, RequestID: requestID}}}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		errorLogger.Printf("request_id=%s error=%s", requestID, err.Error())
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// etagFor returns bk's current ETag, a strong, quoted token per RFC
+// 7232. The book's Version IS the ETag (rather than, say, a hash of the
+// marshaled book) so that a client's If-Match header can be compared
+// directly against the stored attribute with no server-side
+// recomputation, keeping the conditional check in putItem/updateItem
+// atomic (see newVersion).
+func etagFor(bk *book) string {
+	return fmt.Sprintf("%q", bk.Version)
+}
+
+// parseIfMatch strips the quoting from an If-Match header, or returns
+// "" if the header wasn't sent.
+func parseIfMatch(r *http.Request) string {
+	return strings.Trim(r.Header.Get("If-Match"), `"`)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var isbn10Shape = regexp.MustCompile(`^[0-9]{9}[0-9X]$`)
+var isbn13Shape = regexp.MustCompile(`^[0-9]{13}$`)
+
+// validISBN reports whether isbn is a correctly check-digited ISBN-10 or
+// ISBN-13, ignoring any hyphens (e.g. "978-3-16-148410-0").
+func validISBN(isbn string) bool {
+	digits := strings.ReplaceAll(isbn, "-", "")
+
+	switch len(digits) {
+	case 10:
+		return isbn10Shape.MatchString(digits) && isbn10ChecksumValid(digits)
+	case 13:
+		return isbn13Shape.MatchString(digits) && isbn13ChecksumValid(digits)
+	default:
+		return false
+	}
+}
+
+func isbn10ChecksumValid(digits string) bool {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(digits[i]-'0')
+	}
+
+	if last := digits[9]; last == 'X' {
+		sum += 10
+	} else {
+		sum += int(last - '0')
+	}
+
+	return sum%11 == 0
+}
+
+func isbn13ChecksumValid(digits string) bool {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		n := int(digits[i] - '0')
+		if i%2 == 0 {
+			sum += n
+		} else {
+			sum += 3 * n
+		}
+	}
+
+	check, err := strconv.Atoi(string(digits[12]))
+	if err != nil {
+		return false
+	}
+
+	return (10-sum%10)%10 == check
+}
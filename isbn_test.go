@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestValidISBN(t *testing.T) {
+	tests := []struct {
+		name string
+		isbn string
+		want bool
+	}{
+		{"valid isbn-10", "0306406152", true},
+		{"valid isbn-10 with X check digit", "097522980X", true},
+		{"valid isbn-10 with hyphens", "0-306-40615-2", true},
+		{"valid isbn-13", "9780306406157", true},
+		{"valid isbn-13 with hyphens", "978-3-16-148410-0", true},
+		{"isbn-10 bad checksum", "0306406153", false},
+		{"isbn-13 bad checksum", "9780306406158", false},
+		{"isbn-10 wrong length", "030640615", false},
+		{"isbn-13 wrong length", "978030640615", false},
+		{"non-digit characters", "03064061X2", false},
+		{"empty string", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validISBN(tt.isbn); got != tt.want {
+				t.Errorf("validISBN(%q) = %v, want %v", tt.isbn, got, tt.want)
+			}
+		})
+	}
+}
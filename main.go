@@ -6,113 +6,290 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"regexp"
+	"strconv"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jcamilom/books/notify"
 )
 
-var isbnRegexp = regexp.MustCompile(`[0-9]{3}\-[0-9]{10}`)
 var errorLogger = log.New(os.Stderr, "ERROR ", log.Llongfile)
 
 type book struct {
-	ISBN   string `json:"isbn"`
-	Title  string `json:"title"`
-	Author string `json:"author"`
+	ISBN    string `json:"isbn" dynamodbav:"isbn"`
+	Title   string `json:"title" dynamodbav:"title"`
+	Author  string `json:"author" dynamodbav:"author"`
+	Version string `json:"version,omitempty" dynamodbav:"version,omitempty"`
 }
 
-func router(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	switch req.HTTPMethod {
-	case "GET":
-		return show(req)
-	case "POST":
-		return create(req)
+// Validate checks that b has a well-formed ISBN and non-empty
+// title/author, returning a fieldErrors describing every problem at
+// once rather than just the first.
+func (b *book) Validate() error {
+	fe := fieldErrors{}
+	if !validISBN(b.ISBN) {
+		fe["isbn"] = "must be a valid ISBN-10 or ISBN-13"
+	}
+	if b.Title == "" {
+		fe["title"] = "is required"
+	}
+	if b.Author == "" {
+		fe["author"] = "is required"
+	}
+	if len(fe) > 0 {
+		return fe
+	}
+	return nil
+}
+
+// booksHandler dispatches /books requests by HTTP method. It's
+// registered directly on the mux rather than split across per-method
+// mux patterns so local runs and Lambda runs share one routing table.
+func booksHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		show(w, r)
+	case http.MethodPost:
+		create(w, r)
+	case http.MethodPut:
+		replace(w, r)
+	case http.MethodPatch:
+		patch(w, r)
+	case http.MethodDelete:
+		remove(w, r)
 	default:
-		return clientError(http.StatusMethodNotAllowed)
+		clientError(w, r, errCodeUnsupportedMethod, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not supported", r.Method))
 	}
 }
 
-func show(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	// Get the `isbn` query string parameter from the request and
-	// validate it.
-	isbn := req.QueryStringParameters["isbn"]
-	if !isbnRegexp.MatchString(isbn) {
-		return clientError(http.StatusBadRequest)
+func show(w http.ResponseWriter, r *http.Request) {
+	// Without an `isbn` query string parameter there's nothing to look
+	// up, so treat the request as a listing instead.
+	isbn := r.URL.Query().Get("isbn")
+	if isbn == "" {
+		list(w, r)
+		return
+	}
+
+	if !validISBN(isbn) {
+		clientError(w, r, errCodeBadISBN, http.StatusBadRequest, "isbn is missing or malformed")
+		return
 	}
 
 	// Fetch the book record from the database based on the isbn value.
 	bk, err := getItem(isbn)
 	if err != nil {
-		return serverError(err)
+		serverError(w, r, err)
+		return
 	}
 	if bk == nil {
-		return clientError(http.StatusNotFound)
+		clientError(w, r, errCodeNotFound, http.StatusNotFound, "no book with that isbn")
+		return
+	}
+
+	etag := etagFor(bk)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, bk)
+}
+
+// list handles GET /books (no isbn), returning a page of books. Results
+// can be narrowed with ?author= and paged through with ?limit= and
+// ?cursor=.
+func list(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			clientError(w, r, errCodeInvalidLimit, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
 	}
 
-	// The APIGatewayProxyResponse.Body field needs to be a string, so
-	// we marshal the book record into JSON.
-	js, err := json.Marshal(bk)
+	page, err := listItems(limit, r.URL.Query().Get("cursor"), r.URL.Query().Get("author"))
 	if err != nil {
-		return serverError(err)
+		if _, ok := err.(*cursorError); ok {
+			clientError(w, r, errCodeInvalidCursor, http.StatusBadRequest, err.Error())
+			return
+		}
+		serverError(w, r, err)
+		return
 	}
 
-	// Return a response with a 200 OK status and the JSON book record
-	// as the body.
-	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusOK,
-		Body:       string(js),
-	}, nil
+	writeJSON(w, r, http.StatusOK, page)
 }
 
-func create(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if req.Headers["Content-Type"] != "application/json" {
-		return clientError(http.StatusNotAcceptable)
+// replace handles PUT /books?isbn=…, fully overwriting the book record
+// at isbn with the JSON body.
+func replace(w http.ResponseWriter, r *http.Request) {
+	isbn := r.URL.Query().Get("isbn")
+	if !validISBN(isbn) {
+		clientError(w, r, errCodeBadISBN, http.StatusBadRequest, "isbn is missing or malformed")
+		return
+	}
+
+	ifMatch := parseIfMatch(r)
+	if ifMatch == "" {
+		clientError(w, r, errCodePreconditionReq, http.StatusPreconditionRequired, "If-Match header is required")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		clientError(w, r, errCodeUnsupportedMedia, http.StatusNotAcceptable, "Content-Type must be application/json")
+		return
 	}
 
+	// The isbn in the body, if any, is ignored in favor of the one in
+	// the URL, so decode it directly rather than going through bind
+	// (which would validate the body's isbn before we get a chance to
+	// overwrite it).
 	bk := new(book)
-	err := json.Unmarshal([]byte(req.Body), bk)
-	if err != nil {
-		return clientError(http.StatusUnprocessableEntity)
+	if err := json.NewDecoder(r.Body).Decode(bk); err != nil {
+		clientError(w, r, errCodeInvalidBody, http.StatusUnprocessableEntity, "body is not valid JSON")
+		return
+	}
+	bk.ISBN = isbn
+
+	if err := bk.Validate(); err != nil {
+		if fe, ok := err.(fieldErrors); ok {
+			writeFieldErrors(w, r, fe)
+		} else {
+			clientError(w, r, errCodeMissingFields, http.StatusUnprocessableEntity, err.Error())
+		}
+		return
 	}
 
-	if !isbnRegexp.MatchString(bk.ISBN) {
-		return clientError(http.StatusBadRequest)
+	if err := putItem(bk, ifMatch); err != nil {
+		if isConditionalCheckFailed(err) {
+			clientError(w, r, errCodePreconditionFailed, http.StatusPreconditionFailed, "isbn was modified concurrently")
+			return
+		}
+		serverError(w, r, err)
+		return
 	}
-	if bk.Title == "" || bk.Author == "" {
-		return clientError(http.StatusBadRequest)
+	notify.Publish(r.Context(), notifier, notify.Event{Type: notify.BookUpdated, ISBN: bk.ISBN, Title: bk.Title, Author: bk.Author})
+
+	w.Header().Set("ETag", etagFor(bk))
+	writeJSON(w, r, http.StatusOK, bk)
+}
+
+// patch handles PATCH /books?isbn=…, updating only the title/author
+// fields present in the JSON body.
+func patch(w http.ResponseWriter, r *http.Request) {
+	isbn := r.URL.Query().Get("isbn")
+	if !validISBN(isbn) {
+		clientError(w, r, errCodeBadISBN, http.StatusBadRequest, "isbn is missing or malformed")
+		return
 	}
 
-	err = putItem(bk)
+	ifMatch := parseIfMatch(r)
+	if ifMatch == "" {
+		clientError(w, r, errCodePreconditionReq, http.StatusPreconditionRequired, "If-Match header is required")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		clientError(w, r, errCodeUnsupportedMedia, http.StatusNotAcceptable, "Content-Type must be application/json")
+		return
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		clientError(w, r, errCodeInvalidBody, http.StatusUnprocessableEntity, "body is not valid JSON")
+		return
+	}
+
+	fields := make(map[string]string)
+	for _, name := range []string{"title", "author"} {
+		if v, ok := body[name]; ok && v != "" {
+			fields[name] = v
+		}
+	}
+	if len(fields) == 0 {
+		clientError(w, r, errCodeMissingFields, http.StatusBadRequest, "at least one of title, author must be set")
+		return
+	}
+
+	bk, err := updateItem(isbn, fields, ifMatch)
 	if err != nil {
-		return serverError(err)
+		switch err {
+		case errItemNotFound:
+			clientError(w, r, errCodeNotFound, http.StatusNotFound, "no book with that isbn")
+			return
+		case errPreconditionFailed:
+			clientError(w, r, errCodePreconditionFailed, http.StatusPreconditionFailed, "isbn was modified concurrently")
+			return
+		}
+		serverError(w, r, err)
+		return
 	}
+	notify.Publish(r.Context(), notifier, notify.Event{Type: notify.BookUpdated, ISBN: bk.ISBN, Title: bk.Title, Author: bk.Author})
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: 201,
-		Headers:    map[string]string{"Location": fmt.Sprintf("/books?isbn=%s", bk.ISBN)},
-	}, nil
+	w.Header().Set("ETag", etagFor(bk))
+	writeJSON(w, r, http.StatusOK, bk)
 }
 
-// Add a helper for handling errors. This logs any error to os.Stderr
-// and returns a 500 Internal Server Error response that the AWS API
-// Gateway understands.
-func serverError(err error) (events.APIGatewayProxyResponse, error) {
-	errorLogger.Println(err.Error())
+// remove handles DELETE /books?isbn=….
+func remove(w http.ResponseWriter, r *http.Request) {
+	isbn := r.URL.Query().Get("isbn")
+	if !validISBN(isbn) {
+		clientError(w, r, errCodeBadISBN, http.StatusBadRequest, "isbn is missing or malformed")
+		return
+	}
+
+	if err := deleteItem(isbn); err != nil {
+		if isConditionalCheckFailed(err) {
+			clientError(w, r, errCodeNotFound, http.StatusNotFound, "no book with that isbn")
+			return
+		}
+		serverError(w, r, err)
+		return
+	}
+	notify.Publish(r.Context(), notifier, notify.Event{Type: notify.BookDeleted, ISBN: isbn})
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusInternalServerError,
-		Body:       http.StatusText(http.StatusInternalServerError),
-	}, nil
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// Similarly add a helper for send responses relating to client errors.
-func clientError(status int) (events.APIGatewayProxyResponse, error) {
-	return events.APIGatewayProxyResponse{
-		StatusCode: status,
-		Body:       http.StatusText(status),
-	}, nil
+func create(w http.ResponseWriter, r *http.Request) {
+	bk := new(book)
+	if !bind(w, r, bk) {
+		return
+	}
+
+	if err := putItem(bk, ""); err != nil {
+		serverError(w, r, err)
+		return
+	}
+	notify.Publish(r.Context(), notifier, notify.Event{Type: notify.BookCreated, ISBN: bk.ISBN, Title: bk.Title, Author: bk.Author})
+
+	w.Header().Set("ETag", etagFor(bk))
+	w.Header().Set("Location", fmt.Sprintf("/books?isbn=%s", bk.ISBN))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// writeJSON marshals v as the response body with the given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		errorLogger.Printf("request_id=%s error=%s", r.Header.Get(requestIDHeader), err.Error())
+	}
 }
 
 func main() {
-	lambda.Start(router)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/books", booksHandler)
+	handler := withRequestID(mux)
+
+	if os.Getenv("LOCAL_HTTP") == "1" {
+		log.Println("listening on :8080")
+		log.Fatal(http.ListenAndServe(":8080", handler))
+		return
+	}
+
+	lambda.Start(lambdaAdapter(handler))
 }
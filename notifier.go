@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+
+	"github.com/jcamilom/books/notify"
+)
+
+var notifier = newNotifier()
+
+// newNotifier picks a notify.Backend based on which environment
+// variables are configured: an SNS topic ARN selects the SNS backend,
+// otherwise book events are emailed out via SES.
+func newNotifier() notify.Backend {
+	if os.Getenv("BOOKS_NOTIFY_TOPIC_ARN") != "" {
+		return notify.NewSNSBackend()
+	}
+	return notify.NewSESBackend()
+}
@@ -0,0 +1,21 @@
+// Package notify publishes book lifecycle events to subscribers over a
+// pluggable Backend (SES email, SNS, or an in-memory fake for tests).
+package notify
+
+// EventType identifies the kind of book lifecycle event being published.
+type EventType string
+
+const (
+	BookCreated EventType = "BookCreated"
+	BookUpdated EventType = "BookUpdated"
+	BookDeleted EventType = "BookDeleted"
+)
+
+// Event describes something that happened to a book, to be delivered to
+// subscribers by a Backend.
+type Event struct {
+	Type   EventType `json:"type"`
+	ISBN   string    `json:"isbn"`
+	Title  string    `json:"title,omitempty"`
+	Author string    `json:"author,omitempty"`
+}
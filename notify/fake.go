@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeBackend records every Event it receives instead of delivering it
+// anywhere, for use in tests. It's safe for concurrent use; call Events
+// to read the events recorded so far rather than accessing a field
+// directly.
+type FakeBackend struct {
+	mu     sync.Mutex
+	events []Event
+	Err    error
+}
+
+func (b *FakeBackend) Send(_ context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Err != nil {
+		return b.Err
+	}
+	b.events = append(b.events, event)
+	return nil
+}
+
+// Events returns a copy of the events recorded so far.
+func (b *FakeBackend) Events() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make([]Event, len(b.events))
+	copy(events, b.events)
+	return events
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"math"
+	"os"
+	"time"
+)
+
+// Backend delivers a published Event to subscribers. Implementations
+// should not retry internally; Publish handles that.
+type Backend interface {
+	Send(ctx context.Context, event Event) error
+}
+
+var errorLogger = log.New(os.Stderr, "ERROR ", log.Llongfile)
+
+// maxAttempts bounds how many times Publish will try backend.Send
+// before giving up and just logging the failure.
+const maxAttempts = 3
+
+// Publish sends event through backend, retrying with exponential
+// backoff on failure. A delivery failure is logged but never returned
+// to the caller: notifications must not fail the HTTP response that
+// triggered them.
+func Publish(ctx context.Context, backend Backend, event Event) {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff(attempt)):
+			case <-ctx.Done():
+				errorLogger.Printf("event=%s isbn=%s error=%s", event.Type, event.ISBN, ctx.Err())
+				return
+			}
+		}
+
+		if err = backend.Send(ctx, event); err == nil {
+			return
+		}
+	}
+
+	errorLogger.Printf("event=%s isbn=%s attempts=%d error=%s", event.Type, event.ISBN, maxAttempts, err)
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt))) * 100 * time.Millisecond
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+// subjectTemplates and bodyTemplates render the email for each
+// EventType. Kept as plain text/template strings rather than files so
+// the zero-config case needs no extra deploy artifacts.
+var subjectTemplates = map[EventType]*template.Template{
+	BookCreated: template.Must(template.New("subject").Parse("New book added: {{.Title}}")),
+	BookUpdated: template.Must(template.New("subject").Parse("Book updated: {{.Title}}")),
+	BookDeleted: template.Must(template.New("subject").Parse("Book removed: {{.ISBN}}")),
+}
+
+var bodyTemplates = map[EventType]*template.Template{
+	BookCreated: template.Must(template.New("body").Parse("{{.Title}} by {{.Author}} ({{.ISBN}}) was added to the catalog.")),
+	BookUpdated: template.Must(template.New("body").Parse("{{.Title}} by {{.Author}} ({{.ISBN}}) was updated.")),
+	BookDeleted: template.Must(template.New("body").Parse("{{.ISBN}} was removed from the catalog.")),
+}
+
+// SESBackend emails a fixed subscriber list via AWS SES whenever an
+// Event is published.
+type SESBackend struct {
+	client      *ses.SES
+	from        string
+	subscribers []string
+}
+
+// NewSESBackend builds an SESBackend from the BOOKS_NOTIFY_FROM and
+// BOOKS_NOTIFY_SUBSCRIBERS (comma-separated) environment variables.
+func NewSESBackend() *SESBackend {
+	return &SESBackend{
+		client:      ses.New(session.Must(session.NewSession())),
+		from:        os.Getenv("BOOKS_NOTIFY_FROM"),
+		subscribers: splitNonEmpty(os.Getenv("BOOKS_NOTIFY_SUBSCRIBERS"), ","),
+	}
+}
+
+func (b *SESBackend) Send(ctx context.Context, event Event) error {
+	if len(b.subscribers) == 0 {
+		return nil
+	}
+
+	subject, err := render(subjectTemplates[event.Type], event)
+	if err != nil {
+		return err
+	}
+	body, err := render(bodyTemplates[event.Type], event)
+	if err != nil {
+		return err
+	}
+
+	to := make([]*string, len(b.subscribers))
+	for i, addr := range b.subscribers {
+		to[i] = aws.String(addr)
+	}
+
+	_, err = b.client.SendEmailWithContext(ctx, &ses.SendEmailInput{
+		Source:      aws.String(b.from),
+		Destination: &ses.Destination{ToAddresses: to},
+		Message: &ses.Message{
+			Subject: &ses.Content{Data: aws.String(subject)},
+			Body:    &ses.Body{Text: &ses.Content{Data: aws.String(body)}},
+		},
+	})
+	return err
+}
+
+func render(tmpl *template.Template, event Event) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var out []string
+	for _, part := range strings.Split(s, sep) {
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+)
+
+// SNSBackend publishes Events as JSON messages to a single SNS topic.
+type SNSBackend struct {
+	client   *sns.SNS
+	topicARN string
+}
+
+// NewSNSBackend builds an SNSBackend from the BOOKS_NOTIFY_TOPIC_ARN
+// environment variable.
+func NewSNSBackend() *SNSBackend {
+	return &SNSBackend{
+		client:   sns.New(session.Must(session.NewSession())),
+		topicARN: os.Getenv("BOOKS_NOTIFY_TOPIC_ARN"),
+	}
+}
+
+func (b *SNSBackend) Send(ctx context.Context, event Event) error {
+	js, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.PublishWithContext(ctx, &sns.PublishInput{
+		TopicArn: aws.String(b.topicARN),
+		Message:  aws.String(string(js)),
+	})
+	return err
+}
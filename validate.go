@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// validator is implemented by request payloads that need validation
+// beyond basic JSON shape. bind invokes Validate automatically after
+// decoding, so new resources can plug in without re-wiring the
+// Content-Type/decode/validate dance done here.
+type validator interface {
+	Validate() error
+}
+
+// fieldErrors collects one message per invalid field so bind can report
+// all of them in a single 422 instead of stopping at the first.
+type fieldErrors map[string]string
+
+func (e fieldErrors) Error() string {
+	return fmt.Sprintf("validation failed for %d field(s)", len(e))
+}
+
+// bind decodes r's JSON body into target, requiring a Content-Type of
+// application/json, and — if target implements validator — runs
+// Validate() and reports failures as a 422 with per-field details. It
+// writes the response itself on failure and returns false; callers
+// should return immediately when bind returns false.
+func bind(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	if r.Header.Get("Content-Type") != "application/json" {
+		clientError(w, r, errCodeUnsupportedMedia, http.StatusNotAcceptable, "Content-Type must be application/json")
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		clientError(w, r, errCodeInvalidBody, http.StatusUnprocessableEntity, "body is not valid JSON")
+		return false
+	}
+
+	v, ok := target.(validator)
+	if !ok {
+		return true
+	}
+
+	if err := v.Validate(); err != nil {
+		fe, ok := err.(fieldErrors)
+		if !ok {
+			clientError(w, r, errCodeMissingFields, http.StatusUnprocessableEntity, err.Error())
+			return false
+		}
+		writeFieldErrors(w, r, fe)
+		return false
+	}
+
+	return true
+}
+
+// writeFieldErrors writes one apiError per invalid field, all under the
+// MISSING_FIELDS code, as a 422 envelope.
+func writeFieldErrors(w http.ResponseWriter, r *http.Request, fe fieldErrors) {
+	requestID := r.Header.Get(requestIDHeader)
+
+	body := errorEnvelope{Errors: make([]apiError, 0, len(fe))}
+	for field, message := range fe {
+		body.Errors = append(body.Errors, apiError{
+			Code:      errCodeMissingFields,
+			Message:   fmt.Sprintf("%s %s", field, message),
+			RequestID: requestID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		errorLogger.Printf("request_id=%s error=%s", requestID, err.Error())
+	}
+}